@@ -33,12 +33,23 @@ var javaAppName = "jaxb"
 var writeJava = false
 var baseJavaDir = "java"
 
+var writeXsd = false
+
+var streamConvert = false
+var streamElementName = ""
+
+var mavenGroupId = javaBasePackage
+var mavenArtifactId = ""
+var mavenVersion = "1.0-SNAPSHOT"
+
 var namePrefix = "Chi"
 var nameSuffix = ""
 var xmlName = false
 var url = false
 var useType = false
 
+var xpathFilter = ""
+
 type Writer interface {
 	open(s string, lineChannel chan string) error
 	close()
@@ -48,6 +59,8 @@ var outputs = []*bool{
 	&codeGenConvert,
 	&structsToStdout,
 	&writeJava,
+	&writeXsd,
+	&streamConvert,
 }
 
 func init() {
@@ -57,6 +70,12 @@ func init() {
 	flag.BoolVar(&writeJava, "J", writeJava, "Generated Java code for Java/JAXB")
 	flag.StringVar(&baseJavaDir, "D", baseJavaDir, "Base directory for generated Java code (root of maven project)")
 	flag.StringVar(&javaAppName, "k", javaAppName, "App name for Java code (appended to ca.gnewton.chidley Java package name))")
+	flag.StringVar(&mavenGroupId, "g", mavenGroupId, "groupId for the generated Maven pom.xml")
+	flag.StringVar(&mavenArtifactId, "i", mavenArtifactId, "artifactId for the generated Maven pom.xml (default: app name from -k)")
+	flag.StringVar(&mavenVersion, "v", mavenVersion, "version for the generated Maven pom.xml")
+	flag.BoolVar(&writeXsd, "X", writeXsd, "Generate a W3C XML Schema (.xsd) describing the inferred structure and write it to stdout")
+	flag.BoolVar(&streamConvert, "S", streamConvert, "Generate Go code that streams XML to NDJSON (one JSON object per record element) for large inputs, instead of unmarshaling the whole document")
+	flag.StringVar(&streamElementName, "j", streamElementName, "Element name to use as the NDJSON record boundary with -S (default: the first repeating child of the root)")
 
 	flag.BoolVar(&readFromStandardIn, "c", readFromStandardIn, "Read XML from standard input")
 
@@ -69,6 +88,7 @@ func init() {
 	flag.StringVar(&nameSuffix, "s", nameSuffix, "Suffix to struct (element) names")
 	flag.BoolVar(&nameSpaceInJsonName, "n", nameSpaceInJsonName, "Use the XML namespace prefix as prefix to JSON name; prefix followed by 2 underscores (__)")
 	flag.BoolVar(&xmlName, "x", xmlName, "Add XMLName (Space, Local) for each XML element, to JSON")
+	flag.StringVar(&xpathFilter, "P", xpathFilter, "Restrict generation to the subtree(s) matched by this absolute path (element names, * wildcards, *[n] positional and [@attr] existence predicates; [n] on a concrete element name and [@attr='v'] value predicates are rejected)")
 }
 
 func handleParameters() error {
@@ -81,6 +101,10 @@ func handleParameters() error {
 		log.Print("  ERROR: At least one of -W -J -X -V -c must be set")
 	}
 
+	if xpathFilter != "" && !strings.HasPrefix(xpathFilter, "/") {
+		log.Print("  ERROR: -P must be an absolute path, e.g. /feed/entry")
+	}
+
 	return nil
 }
 
@@ -95,45 +119,70 @@ func main() {
 		return
 	}
 
-	if len(flag.Args()) != 1 && !readFromStandardIn {
-		fmt.Println("chidley <flags> xmlFileName|url")
+	if len(flag.Args()) == 0 && !readFromStandardIn {
+		fmt.Println("chidley <flags> xmlFileName|url [xmlFileName|url ...]")
 		fmt.Println("xmlFileName can be .gz or .bz2: uncompressed transparently")
+		fmt.Println("Multiple sample files/urls can be given; their inferred schemas are merged")
 		flag.Usage()
 		return
 	}
 
-	var sourceName string
-
-	if !readFromStandardIn {
-		sourceName = flag.Args()[0]
+	var sourceNames []string
+	if readFromStandardIn {
+		sourceNames = []string{""}
+	} else {
+		sourceNames = flag.Args()
 	}
-	if !url && !readFromStandardIn {
-		sourceName, err = filepath.Abs(sourceName)
+
+	var extractors []*Extractor
+	for _, sn := range sourceNames {
+		if !url && !readFromStandardIn {
+			sn, err = filepath.Abs(sn)
+			if err != nil {
+				log.Fatal("FATAL ERROR: " + err.Error())
+			}
+		}
+
+		source, err := makeSourceReader(sn, url, readFromStandardIn)
 		if err != nil {
 			log.Fatal("FATAL ERROR: " + err.Error())
 		}
-	}
 
-	source, err := makeSourceReader(sourceName, url, readFromStandardIn)
-	if err != nil {
-		log.Fatal("FATAL ERROR: " + err.Error())
-	}
+		e := &Extractor{
+			namePrefix: namePrefix,
+			nameSuffix: nameSuffix,
+			reader:     source.getReader(),
+			useType:    useType,
+			progress:   progress,
+		}
 
-	ex := Extractor{
-		namePrefix: namePrefix,
-		nameSuffix: nameSuffix,
-		reader:     source.getReader(),
-		useType:    useType,
-		progress:   progress,
+		if DEBUG {
+			log.Print("extracting: " + sn)
+		}
+		err = e.extract()
+		if err != nil {
+			log.Fatal("FATAL ERROR: " + err.Error())
+		}
+		extractors = append(extractors, e)
 	}
 
-	if DEBUG {
-		log.Print("extracting")
-	}
-	err = ex.extract()
+	ex := *mergeExtractors(extractors)
+	sourceName := sourceNames[0]
 
-	if err != nil {
-		log.Fatal("FATAL ERROR: " + err.Error())
+	if xpathFilter != "" {
+		if err := validateXPathSegments(parseXPathSegments(xpathFilter)); err != nil {
+			log.Fatal("FATAL ERROR: " + err.Error())
+		}
+		matches := selectXPath(ex.root, xpathFilter, ex.globalTagAttributes)
+		if len(matches) == 0 {
+			log.Fatal("FATAL ERROR: -P path matched no elements: " + xpathFilter)
+		}
+		rebased := &Node{name: ex.root.name, space: ex.root.space, children: make(map[string]*Node)}
+		for _, m := range matches {
+			rebased.children[nk(m)] = m
+		}
+		ex.root = rebased
+		ex.firstNode = matches[0]
 	}
 
 	var writer Writer
@@ -161,6 +210,7 @@ func main() {
 			OneLevelDownXML: makeOneLevelDown(ex.root),
 			Filename:        getFullPath(sourceName),
 			Structs:         sWriter.s,
+			RecordPath:      lastPathElementName(xpathFilter),
 		}
 		t := template.Must(template.New("chidleyGen").Parse(codeTemplate))
 
@@ -195,66 +245,171 @@ func main() {
 			namePrefix:          namePrefix,
 		}
 
-		var onlyChild *Node
-		for _, child := range ex.root.children {
+		// A merged document set can legitimately have more than one
+		// distinct root element, so emit a JAXB class (and matching
+		// Main/package-info) per root observed across all samples
+		// instead of assuming a single one. Each root's package-info goes
+		// in its own sub-package (named after its type) and each Main
+		// class is named after its root type, so they don't overwrite
+		// each other.
+		for _, child := range sortedChildren(ex.root.children) {
 			printJavaJaxbVisitor.Visit(child)
-			// Bad: assume only one base element
-			onlyChild = child
+			rootType := child.makeJavaType(namePrefix, "")
+			rootPackage := lowerFirstLetter(rootType)
+			printJavaJaxbMain(rootType, rootType+"Main", javaDir, javaPackage, getFullPath(sourceName))
+			printPackageInfo(child, javaDir, javaPackage, rootPackage, ex.globalTagAttributes, ex.nameSpaceTagMap)
 		}
-		printJavaJaxbMain(onlyChild.makeJavaType(namePrefix, ""), javaDir, javaPackage, getFullPath(sourceName))
-		printPackageInfo(onlyChild, javaDir, javaPackage, ex.globalTagAttributes, ex.nameSpaceTagMap)
 
 		printMavenPom(baseJavaDir+"/pom.xml", javaAppName)
-	}
-
-}
-
-//func printPackageInfo(node *Node, javaDir string, javaPackage string, globalTagAttributes map[string]) []*FQN {
-func printPackageInfo(node *Node, javaDir string, javaPackage string, globalTagAttributes map[string][]*FQN, nameSpaceTagMap map[string]string) {
 
-	//log.Printf("%+v\n", node)
+	case writeXsd:
+		writer = new(stdoutWriter)
+		writer.open("", lineChannel)
+		printXsdVisitor := new(PrintXsdVisitor)
+		printXsdVisitor.init(lineChannel, ex.globalTagAttributes, ex.nameSpaceTagMap, useType)
+		printXsdVisitor.Visit(ex.root)
+		close(lineChannel)
+		writer.close()
 
-	if node.space != "" {
-		_ = findNameSpaces(globalTagAttributes[nk(node)])
-		//attributes := findNameSpaces(globalTagAttributes[nk(node)])
+	case streamConvert:
+		sWriter := new(stringWriter)
+		writer = sWriter
+		writer.open("", lineChannel)
+		printGoStructVisitor := new(PrintGoStructVisitor)
+		printGoStructVisitor.init(lineChannel, 9999, ex.globalTagAttributes, ex.nameSpaceTagMap, useType, nameSpaceInJsonName)
+		printGoStructVisitor.Visit(ex.root)
+		close(lineChannel)
+		sWriter.close()
 
-		t := template.Must(template.New("package-info").Parse(jaxbPackageInfoTemplage))
-		packageInfoPath := javaDir + "/xml/package-info.java"
-		fi, err := os.Create(packageInfoPath)
-		if err != nil {
-			log.Print("Problem creating file: " + packageInfoPath)
-			panic(err)
+		var recordType *XMLType
+		if streamElementName == "" {
+			recordType = defaultStreamRecordElement(ex.root)
+			if recordType == nil {
+				log.Fatal("FATAL ERROR: could not infer a repeating record element; pass one explicitly with -j")
+			}
+		} else {
+			for _, xt := range makeOneLevelDown(ex.root) {
+				if xt.XMLName == streamElementName {
+					recordType = xt
+					break
+				}
+			}
+			if recordType == nil {
+				log.Fatal("FATAL ERROR: -j element not found one level down from the root: " + streamElementName)
+			}
 		}
-		defer fi.Close()
 
-		writer := bufio.NewWriter(fi)
-		packageInfo := JaxbPackageInfo{
-			BaseNameSpace: node.space,
-			//AdditionalNameSpace []*FQN
-			PackageName: javaPackage + ".xml",
+		x := XmlStreamInfo{
+			BaseXML:           recordType,
+			RecordElementName: recordType.XMLName,
+			Filename:          getFullPath(sourceName),
+			PrettyPrint:       prettyPrint,
+			Structs:           sWriter.s,
 		}
-		err = t.Execute(writer, packageInfo)
+		t := template.Must(template.New("chidleyStreamGen").Parse(streamCodeTemplate))
+
+		err := t.Execute(os.Stdout, x)
 		if err != nil {
 			log.Println("executing template:", err)
 		}
-		bufio.NewWriter(writer).Flush()
 	}
 
 }
 
+// printPackageInfo writes a package-info.java for node's root element
+// under its own sub-package (javaDir+"/xml/"+rootPackage), so that
+// multiple distinct roots observed across merged samples each get their
+// own package-info instead of overwriting a single shared one.
+func printPackageInfo(node *Node, javaDir string, javaPackage string, rootPackage string, globalTagAttributes map[string][]*FQN, nameSpaceTagMap map[string]string) {
+
+	if node.space == "" {
+		return
+	}
+
+	additionalNameSpaces := findNameSpaces(globalTagAttributes[nk(node)], nameSpaceTagMap, node.space)
+
+	t := template.Must(template.New("package-info").Parse(jaxbPackageInfoTemplage))
+	packageInfoDir := javaDir + "/xml/" + rootPackage
+	os.MkdirAll(packageInfoDir, 0755)
+	packageInfoPath := packageInfoDir + "/package-info.java"
+	fi, err := os.Create(packageInfoPath)
+	if err != nil {
+		log.Print("Problem creating file: " + packageInfoPath)
+		panic(err)
+	}
+	defer fi.Close()
+
+	writer := bufio.NewWriter(fi)
+	packageInfo := JaxbPackageInfo{
+		BaseNameSpace:       node.space,
+		AdditionalNameSpace: additionalNameSpaces,
+		ElementFormDefault:  elementFormDefault(node),
+		PackageName:         javaPackage + ".xml." + rootPackage,
+	}
+	err = t.Execute(writer, packageInfo)
+	if err != nil {
+		log.Println("executing template:", err)
+	}
+	bufio.NewWriter(writer).Flush()
+}
+
 const XMLNS = "xmlns"
 
-func findNameSpaces(attributes []*FQN) []*FQN {
-	if attributes == nil || len(attributes) == 0 {
+// findNameSpaces collects the xmlns:* declarations seen as attributes on
+// node and resolves each prefix to its namespace URI via nameSpaceTagMap,
+// reusing the source prefix when the document had one and falling back
+// to a stable ns1, ns2, ... otherwise. node's own base namespace is
+// excluded since the package-info already declares it as the default.
+func findNameSpaces(attributes []*FQN, nameSpaceTagMap map[string]string, baseNameSpace string) []*FQN {
+	if len(attributes) == 0 {
 		return nil
 	}
-	xmlns := make([]*FQN, 0)
-	//for k, v := range attributes {
-	//fmt.Println(k, v)
-	//}
+
+	var xmlns []*FQN
+	seen := make(map[string]bool)
+	counter := 1
+	for _, attr := range attributes {
+		if attr.name != XMLNS && !strings.HasPrefix(attr.name, XMLNS+":") {
+			continue
+		}
+		prefix := strings.TrimPrefix(attr.name, XMLNS+":")
+		uri, ok := nameSpaceTagMap[prefix]
+		if !ok || uri == baseNameSpace || seen[uri] {
+			continue
+		}
+		seen[uri] = true
+		if prefix == XMLNS {
+			prefix = fmt.Sprintf("ns%d", counter)
+			counter++
+		}
+		xmlns = append(xmlns, &FQN{name: prefix, space: uri})
+	}
 	return xmlns
 }
 
+// elementFormDefault reports QUALIFIED when any descendant of node, at
+// any depth, lives in a different namespace than its own parent, which
+// JAXB needs in order to marshal that element with its own namespace
+// qualifier.
+func elementFormDefault(node *Node) string {
+	if anyChildChangesNameSpace(node) {
+		return "QUALIFIED"
+	}
+	return "UNQUALIFIED"
+}
+
+func anyChildChangesNameSpace(node *Node) bool {
+	for _, child := range node.children {
+		if child.space != node.space {
+			return true
+		}
+		if anyChildChangesNameSpace(child) {
+			return true
+		}
+	}
+	return false
+}
+
 func printMavenPom(pomPath string, javaAppName string) {
 	t := template.Must(template.New("mavenPom").Parse(mavenPomTemplate))
 	fi, err := os.Create(pomPath)
@@ -264,9 +419,17 @@ func printMavenPom(pomPath string, javaAppName string) {
 	}
 	defer fi.Close()
 
+	artifactId := mavenArtifactId
+	if artifactId == "" {
+		artifactId = javaAppName
+	}
+
 	writer := bufio.NewWriter(fi)
 	maven := JaxbMavenPomInfo{
-		AppName: javaAppName,
+		AppName:    javaAppName,
+		GroupId:    mavenGroupId,
+		ArtifactId: artifactId,
+		Version:    mavenVersion,
 	}
 	err = t.Execute(writer, maven)
 	if err != nil {
@@ -275,9 +438,9 @@ func printMavenPom(pomPath string, javaAppName string) {
 	bufio.NewWriter(writer).Flush()
 }
 
-func printJavaJaxbMain(rootElementName string, javaDir string, javaPackage string, sourceXMLFilename string) {
+func printJavaJaxbMain(rootElementName string, className string, javaDir string, javaPackage string, sourceXMLFilename string) {
 	t := template.Must(template.New("chidleyJaxbGenClass").Parse(jaxbMainTemplate))
-	writer, f, err := javaClassWriter(javaDir, javaPackage, "Main")
+	writer, f, err := javaClassWriter(javaDir, javaPackage, className)
 	defer f.Close()
 
 	classInfo := JaxbMainClassInfo{
@@ -356,6 +519,93 @@ func countNumberOfBoolsSet(a []*bool) int {
 	return counter
 }
 
+// optionalNodes tracks elements discovered by mergeExtractors that were
+// not present in every sample, so the struct/JAXB visitors can mark them
+// accordingly (",omitempty" / "required=false"). Attributes carry the
+// same information in their own FQN.optional flag.
+var optionalNodes = make(map[*Node]bool)
+
+// mergeExtractors unions the element trees and attribute sets discovered
+// across multiple XML samples into a single Extractor. Real-world feeds
+// rarely have every optional element/attribute present in any one file,
+// so the first sample is used as the base and every other sample is
+// merged into it rather than regenerating types per-file.
+func mergeExtractors(extractors []*Extractor) *Extractor {
+	merged := extractors[0]
+	for _, e := range extractors[1:] {
+		mergeNode(merged.root, e.root)
+		mergeGlobalTagAttributes(merged.globalTagAttributes, e.globalTagAttributes)
+		mergeNameSpaceTagMap(merged.nameSpaceTagMap, e.nameSpaceTagMap)
+	}
+	return merged
+}
+
+// mergeNode unions other's children into base, recursing into children
+// present on both sides. A child present on only one side is marked
+// optional, since it did not appear in every sample.
+func mergeNode(base *Node, other *Node) {
+	if base == nil || other == nil {
+		return
+	}
+	if base.children == nil {
+		base.children = make(map[string]*Node)
+	}
+	for k := range base.children {
+		if _, ok := other.children[k]; !ok {
+			optionalNodes[base.children[k]] = true
+		}
+	}
+	for k, child := range other.children {
+		if existing, ok := base.children[k]; ok {
+			mergeNode(existing, child)
+		} else {
+			optionalNodes[child] = true
+			base.children[k] = child
+		}
+	}
+}
+
+// mergeGlobalTagAttributes unions the attribute FQNs discovered per tag
+// across samples, and marks an attribute optional when a sample is
+// missing it. When -t is on and two samples disagree on an attribute's
+// inferred type, the type is promoted to string rather than guessed.
+func mergeGlobalTagAttributes(base map[string][]*FQN, other map[string][]*FQN) {
+	for k, otherFqns := range other {
+		baseFqns := base[k]
+		seen := make(map[string]*FQN, len(baseFqns))
+		for _, f := range baseFqns {
+			seen[f.name] = f
+		}
+		otherNames := make(map[string]bool, len(otherFqns))
+		for _, f := range otherFqns {
+			otherNames[f.name] = true
+			if existing, ok := seen[f.name]; ok {
+				if useType && existing.useType != f.useType {
+					existing.useType = "string"
+				}
+			} else {
+				f.optional = true
+				baseFqns = append(baseFqns, f)
+				seen[f.name] = f
+			}
+		}
+		for _, f := range baseFqns {
+			if !otherNames[f.name] {
+				f.optional = true
+			}
+		}
+		base[k] = baseFqns
+	}
+}
+
+func mergeNameSpaceTagMap(base map[string]string, other map[string]string) {
+	for k, v := range other {
+		if _, ok := base[k]; !ok {
+			base[k] = v
+		}
+	}
+}
+
 func makeOneLevelDown(node *Node) []*XMLType {
 	var children []*XMLType
 
@@ -0,0 +1,74 @@
+package main
+
+// XmlInfo is the template data for codeTemplate, the -W output mode that
+// generates a small standalone Go program converting an XML document to
+// JSON.
+type XmlInfo struct {
+	BaseXML         *XMLType
+	OneLevelDownXML []*XMLType
+	Filename        string
+	Structs         string
+	// RecordPath is the element name of a -P subtree filter's last
+	// segment, if one was given. When set, the generated program
+	// stream-matches that element via xml.Decoder.Token() and skips
+	// every other sibling instead of unmarshaling the whole file.
+	RecordPath string
+}
+
+const codeTemplate = `package main
+
+// Generated by chidley -W from {{.Filename}}
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"log"
+	"os"
+)
+
+{{.Structs}}
+
+func main() {
+	f, err := os.Open("{{.Filename}}")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(os.Stdout)
+{{if .RecordPath}}
+	decoder := xml.NewDecoder(f)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "{{.RecordPath}}" {
+			continue
+		}
+
+		var record {{.BaseXML.NameType}}
+		if err := decoder.DecodeElement(&record, &start); err != nil {
+			log.Fatal(err)
+		}
+		if err := enc.Encode(record); err != nil {
+			log.Fatal(err)
+		}
+	}
+{{else}}
+	var root {{.BaseXML.NameType}}
+	if err := xml.NewDecoder(f).Decode(&root); err != nil {
+		log.Fatal(err)
+	}
+	if err := enc.Encode(root); err != nil {
+		log.Fatal(err)
+	}
+{{end}}
+}
+`
@@ -0,0 +1,94 @@
+package main
+
+import "fmt"
+
+// PrintGoStructVisitor walks the tree discovered by Extractor and emits
+// the Go struct definitions used by the -W/-G/-S output modes. Elements
+// and attributes that mergeExtractors found missing from at least one
+// sample get ",omitempty" on their JSON tag, since they are not
+// guaranteed to be present on every document.
+type PrintGoStructVisitor struct {
+	lineChannel         chan string
+	indent              int
+	globalTagAttributes map[string][]*FQN
+	nameSpaceTagMap     map[string]string
+	useType             bool
+	nameSpaceInJsonName bool
+	alreadyVisited      map[string]bool
+}
+
+func (v *PrintGoStructVisitor) init(lineChannel chan string, indent int, globalTagAttributes map[string][]*FQN, nameSpaceTagMap map[string]string, useType bool, nameSpaceInJsonName bool) {
+	v.lineChannel = lineChannel
+	v.indent = indent
+	v.globalTagAttributes = globalTagAttributes
+	v.nameSpaceTagMap = nameSpaceTagMap
+	v.useType = useType
+	v.nameSpaceInJsonName = nameSpaceInJsonName
+	v.alreadyVisited = make(map[string]bool)
+}
+
+func (v *PrintGoStructVisitor) Visit(node *Node) {
+	key := nk(node)
+	if v.alreadyVisited[key] {
+		return
+	}
+	v.alreadyVisited[key] = true
+
+	for _, child := range sortedChildren(node.children) {
+		v.Visit(child)
+	}
+
+	v.lineChannel <- fmt.Sprintf("type %s struct {", node.makeType(namePrefix, nameSuffix))
+	for _, attr := range v.globalTagAttributes[key] {
+		v.lineChannel <- v.attributeField(attr)
+	}
+	for _, child := range sortedChildren(node.children) {
+		v.lineChannel <- v.childField(child)
+	}
+	v.lineChannel <- "}"
+	v.lineChannel <- ""
+}
+
+func (v *PrintGoStructVisitor) childField(child *Node) string {
+	jsonName := v.jsonName(child)
+	if optionalNodes[child] {
+		jsonName += ",omitempty"
+	}
+	fieldType := child.makeType(namePrefix, nameSuffix)
+	if child.repeated {
+		fieldType = "[]" + fieldType
+	}
+	return fmt.Sprintf("\t%s %s `json:\"%s\"`", capitalizeFirstLetter(child.name), fieldType, jsonName)
+}
+
+func (v *PrintGoStructVisitor) jsonName(node *Node) string {
+	if v.nameSpaceInJsonName && node.space != "" {
+		return node.space + "__" + node.name
+	}
+	return node.name
+}
+
+func (v *PrintGoStructVisitor) attributeField(attr *FQN) string {
+	jsonName := attr.name + ",attr"
+	if attr.optional {
+		jsonName += ",omitempty"
+	}
+	fieldName := attributePrefix + capitalizeFirstLetter(attr.name)
+	return fmt.Sprintf("\t%s %s `json:\"%s\"`", fieldName, v.attrGoType(attr), jsonName)
+}
+
+func (v *PrintGoStructVisitor) attrGoType(attr *FQN) string {
+	if !v.useType {
+		return "string"
+	}
+	switch attr.useType {
+	case "int", "int64":
+		return "int64"
+	case "bool":
+		return "bool"
+	case "float64":
+		return "float64"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+// JaxbMavenPomInfo is the template data for mavenPomTemplate: the pom.xml
+// chidley -J writes alongside the generated JAXB sources.
+type JaxbMavenPomInfo struct {
+	AppName    string
+	GroupId    string
+	ArtifactId string
+	Version    string
+}
+
+const mavenPomTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0"
+         xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+         xsi:schemaLocation="http://maven.apache.org/POM/4.0.0 http://maven.apache.org/xsd/maven-4.0.0.xsd">
+  <modelVersion>4.0.0</modelVersion>
+
+  <groupId>{{.GroupId}}</groupId>
+  <artifactId>{{.ArtifactId}}</artifactId>
+  <version>{{.Version}}</version>
+  <packaging>jar</packaging>
+
+  <properties>
+    <maven.compiler.source>11</maven.compiler.source>
+    <maven.compiler.target>11</maven.compiler.target>
+    <project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>
+  </properties>
+
+  <dependencies>
+    <dependency>
+      <groupId>jakarta.xml.bind</groupId>
+      <artifactId>jakarta.xml.bind-api</artifactId>
+      <version>4.0.0</version>
+    </dependency>
+    <dependency>
+      <groupId>org.glassfish.jaxb</groupId>
+      <artifactId>jaxb-runtime</artifactId>
+      <version>4.0.3</version>
+      <scope>runtime</scope>
+    </dependency>
+  </dependencies>
+</project>
+`
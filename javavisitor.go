@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+)
+
+// PrintJavaJaxbVisitor walks the tree discovered by Extractor and emits
+// one JAXB-annotated Java class per element for the -J output mode.
+// Elements and attributes that mergeExtractors found missing from at
+// least one sample get "required = false", since they are not
+// guaranteed to be present on every document.
+type PrintJavaJaxbVisitor struct {
+	alreadyVisited      map[string]bool
+	globalTagAttributes map[string][]*FQN
+	nameSpaceTagMap     map[string]string
+	useType             bool
+	javaDir             string
+	javaPackage         string
+	namePrefix          string
+}
+
+func (v *PrintJavaJaxbVisitor) Visit(node *Node) {
+	key := nk(node)
+	if v.alreadyVisited[key] {
+		return
+	}
+	v.alreadyVisited[key] = true
+
+	for _, child := range sortedChildren(node.children) {
+		v.Visit(child)
+	}
+
+	className := node.makeJavaType(v.namePrefix, "")
+	writer, f, err := javaClassWriter(v.javaDir, v.javaPackage, className)
+	if err != nil {
+		log.Println("creating java class writer:", err)
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintf(writer, "package %s;\n\n", v.javaPackage)
+	fmt.Fprintln(writer, "import jakarta.xml.bind.annotation.*;")
+	fmt.Fprintln(writer, "import java.util.List;")
+	fmt.Fprintln(writer)
+	fmt.Fprintln(writer, "@XmlAccessorType(XmlAccessType.FIELD)")
+	fmt.Fprintf(writer, "public class %s {\n", className)
+
+	for _, attr := range v.globalTagAttributes[key] {
+		fmt.Fprintf(writer, "\t@XmlAttribute(name = \"%s\", required = %t)\n", attr.name, !attr.optional)
+		fmt.Fprintf(writer, "\tprivate %s %s;\n\n", v.attrJavaType(attr), lowerFirstLetter(capitalizeFirstLetter(attr.name)))
+	}
+
+	for _, child := range sortedChildren(node.children) {
+		fieldType := child.makeJavaType(v.namePrefix, "")
+		if child.repeated {
+			fieldType = "List<" + fieldType + ">"
+		}
+		fmt.Fprintf(writer, "\t@XmlElement(name = \"%s\", required = %t)\n", child.name, !optionalNodes[child])
+		fmt.Fprintf(writer, "\tprivate %s %s;\n\n", fieldType, lowerFirstLetter(child.name))
+	}
+
+	fmt.Fprintln(writer, "}")
+	bufio.NewWriter(writer).Flush()
+}
+
+func (v *PrintJavaJaxbVisitor) attrJavaType(attr *FQN) string {
+	if !v.useType {
+		return "String"
+	}
+	switch attr.useType {
+	case "int", "int64":
+		return "Long"
+	case "bool":
+		return "Boolean"
+	case "float64":
+		return "Double"
+	default:
+		return "String"
+	}
+}
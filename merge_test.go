@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeGlobalTagAttributes(t *testing.T) {
+	cases := []struct {
+		name    string
+		base    map[string][]*FQN
+		other   map[string][]*FQN
+		wantOpt map[string]bool
+	}{
+		{
+			name: "attribute missing from other sample becomes optional",
+			base: map[string][]*FQN{
+				"tag": {{name: "id"}, {name: "type"}},
+			},
+			other: map[string][]*FQN{
+				"tag": {{name: "id"}},
+			},
+			wantOpt: map[string]bool{"id": false, "type": true},
+		},
+		{
+			name: "attribute only in other sample is added as optional",
+			base: map[string][]*FQN{
+				"tag": {{name: "id"}},
+			},
+			other: map[string][]*FQN{
+				"tag": {{name: "id"}, {name: "lang"}},
+			},
+			wantOpt: map[string]bool{"id": false, "lang": true},
+		},
+		{
+			name: "attribute present in both stays required",
+			base: map[string][]*FQN{
+				"tag": {{name: "id"}},
+			},
+			other: map[string][]*FQN{
+				"tag": {{name: "id"}},
+			},
+			wantOpt: map[string]bool{"id": false},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			mergeGlobalTagAttributes(c.base, c.other)
+			got := make(map[string]bool)
+			for _, f := range c.base["tag"] {
+				got[f.name] = f.optional
+			}
+			if !reflect.DeepEqual(got, c.wantOpt) {
+				t.Errorf("optional flags = %+v, want %+v", got, c.wantOpt)
+			}
+		})
+	}
+}
+
+func TestMergeNodeMarksMissingChildrenOptional(t *testing.T) {
+	base := &Node{name: "root", children: map[string]*Node{
+		"a": {name: "a"},
+		"b": {name: "b"},
+	}}
+	other := &Node{name: "root", children: map[string]*Node{
+		"a": {name: "a"},
+		"c": {name: "c"},
+	}}
+
+	mergeNode(base, other)
+
+	if !optionalNodes[base.children["b"]] {
+		t.Error("b should be optional: missing from the other sample")
+	}
+	if !optionalNodes[base.children["c"]] {
+		t.Error("c should be optional: missing from the base sample")
+	}
+	if optionalNodes[base.children["a"]] {
+		t.Error("a should not be optional: present in both samples")
+	}
+	if len(base.children) != 3 {
+		t.Errorf("expected 3 children after merge, got %d", len(base.children))
+	}
+}
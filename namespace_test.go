@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestFindNameSpaces(t *testing.T) {
+	nameSpaceTagMap := map[string]string{
+		"gd": "http://schemas.google.com/g/2005",
+	}
+	attributes := []*FQN{
+		{name: "xmlns:gd"},
+		{name: "id"},
+	}
+
+	got := findNameSpaces(attributes, nameSpaceTagMap, "http://www.w3.org/2005/Atom")
+
+	if len(got) != 1 || got[0].name != "gd" || got[0].space != "http://schemas.google.com/g/2005" {
+		t.Errorf("findNameSpaces() = %+v, want one FQN{name: \"gd\", space: \"http://schemas.google.com/g/2005\"}", got)
+	}
+}
+
+func TestFindNameSpacesSkipsBaseNameSpace(t *testing.T) {
+	baseNameSpace := "http://www.w3.org/2005/Atom"
+	nameSpaceTagMap := map[string]string{
+		"xmlns": baseNameSpace,
+	}
+	attributes := []*FQN{
+		{name: "xmlns"},
+	}
+
+	got := findNameSpaces(attributes, nameSpaceTagMap, baseNameSpace)
+
+	if got != nil {
+		t.Errorf("findNameSpaces() = %+v, want nil: node's own base namespace should be excluded", got)
+	}
+}
+
+func TestElementFormDefaultRecursive(t *testing.T) {
+	root := &Node{name: "feed", space: "atom", children: map[string]*Node{
+		"entry": {name: "entry", space: "atom", children: map[string]*Node{
+			"id": {name: "id", space: "gd"},
+		}},
+	}}
+
+	if got := elementFormDefault(root); got != "QUALIFIED" {
+		t.Errorf("elementFormDefault() = %q, want QUALIFIED: a grandchild changes namespace", got)
+	}
+}
+
+func TestElementFormDefaultUnqualified(t *testing.T) {
+	root := &Node{name: "feed", space: "atom", children: map[string]*Node{
+		"entry": {name: "entry", space: "atom", children: map[string]*Node{
+			"id": {name: "id", space: "atom"},
+		}},
+	}}
+
+	if got := elementFormDefault(root); got != "UNQUALIFIED" {
+		t.Errorf("elementFormDefault() = %q, want UNQUALIFIED: no descendant changes namespace", got)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+// XmlStreamInfo is the template data for streamCodeTemplate: a small Go
+// program that walks the input with xml.Decoder.Token() and writes one
+// JSON object per RecordElementName occurrence to stdout as NDJSON,
+// instead of unmarshaling the whole document like codeTemplate does.
+type XmlStreamInfo struct {
+	BaseXML           *XMLType
+	RecordElementName string
+	Filename          string
+	PrettyPrint       bool
+	Structs           string
+}
+
+// defaultStreamRecordElement picks the -j default: the first repeating
+// child found two levels down from root, visited in deterministic
+// (sorted) order so the same input always yields the same record
+// boundary, rather than whatever a map iteration happens to produce.
+// When no child is marked as repeating, it falls back to the first
+// grandchild in that same deterministic order.
+func defaultStreamRecordElement(root *Node) *XMLType {
+	var fallback *XMLType
+	for _, child := range sortedChildren(root.children) {
+		for _, grandchild := range sortedChildren(child.children) {
+			xt := &XMLType{NameType: grandchild.makeType(namePrefix, nameSuffix),
+				XMLName:      grandchild.name,
+				XMLNameUpper: capitalizeFirstLetter(grandchild.name),
+				XMLSpace:     grandchild.space,
+			}
+			if grandchild.repeated {
+				return xt
+			}
+			if fallback == nil {
+				fallback = xt
+			}
+		}
+	}
+	return fallback
+}
+
+const streamCodeTemplate = `package main
+
+// Generated by chidley -S: streams {{.Filename}} and writes one JSON
+// object per <{{.RecordElementName}}> element to stdout as NDJSON.
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"log"
+	"os"
+)
+
+{{.Structs}}
+
+func main() {
+	f, err := os.Open("{{.Filename}}")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(os.Stdout)
+	if {{.PrettyPrint}} {
+		enc.SetIndent("", "  ")
+	}
+	decoder := xml.NewDecoder(f)
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "{{.RecordElementName}}" {
+			continue
+		}
+
+		var record {{.BaseXML.NameType}}
+		if err := decoder.DecodeElement(&record, &start); err != nil {
+			log.Fatal(err)
+		}
+		if err := enc.Encode(record); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+`
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// xpathSegment is one "/"-separated step of the restricted XPath subset
+// handled by selectXPath: an element name (or "*"), optionally followed
+// by a "[n]" positional predicate or a "[@attr='v']" attribute predicate.
+type xpathSegment struct {
+	name      string
+	index     int
+	attrName  string
+	attrValue string
+}
+
+// selectXPath evaluates an absolute path like "/feed/entry[@type]"
+// against the tree rooted at root, returning every node it matches. An
+// attribute predicate matches on the attribute's presence only; a value
+// given as [@attr='v'] is not compared. It is
+// intentionally a small subset of XPath, not a general implementation,
+// modeled after the element/path navigation style of libraries such as
+// beevik/etree.
+func selectXPath(root *Node, path string, globalTagAttributes map[string][]*FQN) []*Node {
+	segments := parseXPathSegments(path)
+	current := []*Node{root}
+	for _, seg := range segments {
+		var next []*Node
+		for _, n := range current {
+			next = append(next, matchSegment(n, seg, globalTagAttributes)...)
+		}
+		current = next
+	}
+	return current
+}
+
+func parseXPathSegments(path string) []xpathSegment {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	parts := strings.Split(path, "/")
+	segments := make([]xpathSegment, 0, len(parts))
+	for _, p := range parts {
+		segments = append(segments, parseXPathSegment(p))
+	}
+	return segments
+}
+
+func parseXPathSegment(p string) xpathSegment {
+	seg := xpathSegment{name: p}
+
+	open := strings.Index(p, "[")
+	if open < 0 || !strings.HasSuffix(p, "]") {
+		return seg
+	}
+
+	seg.name = p[:open]
+	predicate := p[open+1 : len(p)-1]
+
+	if strings.HasPrefix(predicate, "@") {
+		if eq := strings.Index(predicate, "="); eq > 0 {
+			seg.attrName = strings.TrimPrefix(predicate[:eq], "@")
+			seg.attrValue = strings.Trim(predicate[eq+1:], `'"`)
+		} else {
+			seg.attrName = strings.TrimPrefix(predicate, "@")
+		}
+		return seg
+	}
+
+	if idx, err := strconv.Atoi(predicate); err == nil {
+		seg.index = idx
+	}
+	return seg
+}
+
+// validateXPathSegments rejects two combinations selectXPath cannot
+// honor against chidley's schema tree:
+//
+//   - an [n] predicate on a concrete element name. node.children is
+//     deduplicated one node per distinct tag name, so a concrete name
+//     only ever has a single candidate and [n] for n>1 could never
+//     match; it is only meaningful combined with a "*" wildcard, which
+//     can span several distinct child tag names.
+//   - an [@attr='value'] predicate. The schema tree only tracks which
+//     attributes were observed on an element, not the values they took,
+//     so a value comparison cannot be evaluated; only existence via
+//     [@attr] is supported.
+func validateXPathSegments(segments []xpathSegment) error {
+	for _, seg := range segments {
+		if seg.index > 0 && seg.name != "*" {
+			return fmt.Errorf("-P: [%d] on element name %q can never match (chidley's schema tree has at most one %q per parent); use \"*[%d]\" instead", seg.index, seg.name, seg.name, seg.index)
+		}
+		if seg.attrValue != "" {
+			return fmt.Errorf("-P: [@%s='%s'] attribute-value predicates are not supported (only attribute existence is tracked); use [@%s] instead", seg.attrName, seg.attrValue, seg.attrName)
+		}
+	}
+	return nil
+}
+
+// sortedChildren returns node's children in a deterministic order (by
+// name), since Go map iteration order is randomized per run and a [n]
+// positional predicate is meaningless without a stable order to count
+// against.
+func sortedChildren(children map[string]*Node) []*Node {
+	ordered := make([]*Node, 0, len(children))
+	for _, child := range children {
+		ordered = append(ordered, child)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		return ordered[i].name < ordered[j].name
+	})
+	return ordered
+}
+
+// matchSegment returns node's children that satisfy seg, in
+// sortedChildren order; a [n] predicate then picks the n-th (1-based) of
+// those.
+func matchSegment(node *Node, seg xpathSegment, globalTagAttributes map[string][]*FQN) []*Node {
+	if node == nil {
+		return nil
+	}
+
+	var candidates []*Node
+	for _, child := range sortedChildren(node.children) {
+		if seg.name == "*" || child.name == seg.name {
+			candidates = append(candidates, child)
+		}
+	}
+
+	if seg.attrName != "" {
+		var withAttr []*Node
+		for _, c := range candidates {
+			if hasAttribute(globalTagAttributes[nk(c)], seg.attrName) {
+				withAttr = append(withAttr, c)
+			}
+		}
+		candidates = withAttr
+	}
+
+	if seg.index > 0 {
+		if seg.index > len(candidates) {
+			return nil
+		}
+		candidates = candidates[seg.index-1 : seg.index]
+	}
+
+	return candidates
+}
+
+// lastPathElementName returns the element name of the final segment of
+// a -P path (e.g. "entry" for "/feed/entry[@type='text']"), or "" if no
+// path was given. It is used to tell the -W runtime converter which
+// element to stream-match on, so it can skip uninteresting siblings
+// instead of unmarshaling the whole file.
+func lastPathElementName(path string) string {
+	segments := parseXPathSegments(path)
+	if len(segments) == 0 {
+		return ""
+	}
+	return segments[len(segments)-1].name
+}
+
+func hasAttribute(attrs []*FQN, name string) bool {
+	for _, a := range attrs {
+		if a.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestParseXPathSegment(t *testing.T) {
+	cases := []struct {
+		in   string
+		want xpathSegment
+	}{
+		{"feed", xpathSegment{name: "feed"}},
+		{"*", xpathSegment{name: "*"}},
+		{"entry[2]", xpathSegment{name: "entry", index: 2}},
+		{"entry[@type]", xpathSegment{name: "entry", attrName: "type"}},
+		{"entry[@type='text']", xpathSegment{name: "entry", attrName: "type", attrValue: "text"}},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			got := parseXPathSegment(c.in)
+			if got != c.want {
+				t.Errorf("parseXPathSegment(%q) = %+v, want %+v", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidateXPathSegments(t *testing.T) {
+	cases := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"plain path is fine", "/feed/entry", false},
+		{"wildcard with index is fine", "/feed/*[2]", false},
+		{"index on a concrete name is rejected", "/feed/entry[2]", true},
+		{"attribute existence predicate is fine", "/feed/entry[@type]", false},
+		{"attribute value predicate is rejected", "/feed/entry[@type='text']", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := validateXPathSegments(parseXPathSegments(c.path))
+			if (err != nil) != c.wantErr {
+				t.Errorf("validateXPathSegments(%q) err = %v, wantErr %v", c.path, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestSortedChildrenIsDeterministic(t *testing.T) {
+	children := map[string]*Node{
+		"c": {name: "c"},
+		"a": {name: "a"},
+		"b": {name: "b"},
+	}
+	for i := 0; i < 5; i++ {
+		ordered := sortedChildren(children)
+		if len(ordered) != 3 || ordered[0].name != "a" || ordered[1].name != "b" || ordered[2].name != "c" {
+			t.Fatalf("sortedChildren not in sorted order: %+v", ordered)
+		}
+	}
+}
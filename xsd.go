@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PrintXsdVisitor walks the tree discovered by Extractor and emits a W3C
+// XML Schema describing the elements, attributes and inferred simple
+// types, so chidley's output can feed other XML toolchains (xjc,
+// xmllint --schema, etc.) instead of only Go/Java code.
+type PrintXsdVisitor struct {
+	lineChannel         chan string
+	globalTagAttributes map[string][]*FQN
+	nameSpaceTagMap     map[string]string
+	useType             bool
+	alreadyVisited      map[string]bool
+	nsPrefixByUri       map[string]string
+}
+
+func (v *PrintXsdVisitor) init(lineChannel chan string, globalTagAttributes map[string][]*FQN, nameSpaceTagMap map[string]string, useType bool) {
+	v.lineChannel = lineChannel
+	v.globalTagAttributes = globalTagAttributes
+	v.nameSpaceTagMap = nameSpaceTagMap
+	v.useType = useType
+	v.alreadyVisited = make(map[string]bool)
+
+	// Build a stable uri->prefix lookup (sorted so two prefixes sharing a
+	// uri deterministically pick the same winner every run) for
+	// namespace-qualifying complexType names.
+	v.nsPrefixByUri = make(map[string]string)
+	for _, prefix := range sortedKeys(nameSpaceTagMap) {
+		uri := nameSpaceTagMap[prefix]
+		if _, ok := v.nsPrefixByUri[uri]; !ok {
+			v.nsPrefixByUri[uri] = prefix
+		}
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (v *PrintXsdVisitor) Visit(node *Node) {
+	v.lineChannel <- `<?xml version="1.0" encoding="UTF-8"?>`
+	v.lineChannel <- v.schemaOpenTag()
+	for _, prefix := range sortedKeys(v.nameSpaceTagMap) {
+		v.lineChannel <- fmt.Sprintf(`  <xs:import namespace="%s" schemaLocation="%s.xsd"/>`, v.nameSpaceTagMap[prefix], prefix)
+	}
+	for _, child := range sortedChildren(node.children) {
+		v.visitElement(child)
+	}
+	v.lineChannel <- `</xs:schema>`
+}
+
+func (v *PrintXsdVisitor) schemaOpenTag() string {
+	return `<xs:schema xmlns:xs="http://www.w3.org/2001/XMLSchema" elementFormDefault="qualified">`
+}
+
+func (v *PrintXsdVisitor) visitElement(node *Node) {
+	key := nk(node)
+	if v.alreadyVisited[key] {
+		return
+	}
+	v.alreadyVisited[key] = true
+
+	hasContent := len(node.children) > 0 || len(v.globalTagAttributes[key]) > 0
+	if !hasContent {
+		// A leaf element has no complexType of its own; referencing one
+		// that is never defined would make the schema unparseable, so
+		// it gets the inferred simple type directly.
+		v.lineChannel <- fmt.Sprintf(`  <xs:element name="%s" type="%s"/>`, node.name, v.nodeSimpleType(node))
+		return
+	}
+
+	v.lineChannel <- fmt.Sprintf(`  <xs:element name="%s" type="%s"/>`, node.name, v.typeName(node))
+	v.lineChannel <- fmt.Sprintf(`  <xs:complexType name="%s">`, v.typeName(node))
+	if len(node.children) > 0 {
+		v.lineChannel <- `    <xs:sequence>`
+		for _, child := range sortedChildren(node.children) {
+			minOccurs := "1"
+			maxOccurs := "1"
+			if optionalNodes[child] {
+				minOccurs = "0"
+			}
+			if child.repeated {
+				maxOccurs = "unbounded"
+			}
+			v.lineChannel <- fmt.Sprintf(`      <xs:element ref="%s" minOccurs="%s" maxOccurs="%s"/>`, child.name, minOccurs, maxOccurs)
+		}
+		v.lineChannel <- `    </xs:sequence>`
+	}
+	for _, attr := range v.globalTagAttributes[key] {
+		v.lineChannel <- v.attributeLine(attr)
+	}
+	v.lineChannel <- `  </xs:complexType>`
+
+	for _, child := range sortedChildren(node.children) {
+		v.visitElement(child)
+	}
+}
+
+// typeName qualifies the complexType name by namespace prefix so that
+// two distinct elements sharing a local name in different namespaces
+// (e.g. ns1:id and ns2:id, which are distinct nk() keys and so are both
+// visited) don't collide on the same top-level type name.
+func (v *PrintXsdVisitor) typeName(node *Node) string {
+	base := capitalizeFirstLetter(node.name) + "Type"
+	if node.space == "" {
+		return base
+	}
+	if prefix, ok := v.nsPrefixByUri[node.space]; ok && prefix != "" {
+		return capitalizeFirstLetter(prefix) + base
+	}
+	return base
+}
+
+func (v *PrintXsdVisitor) attributeLine(attr *FQN) string {
+	use := `use="required"`
+	if attr.optional {
+		use = `use="optional"`
+	}
+	return fmt.Sprintf(`    <xs:attribute name="%s" type="%s" %s/>`, attr.name, v.simpleType(attr), use)
+}
+
+// simpleType maps chidley's inferred Go type (when -t is on) to the
+// closest xs:simpleType restriction; everything else stays xs:string.
+func (v *PrintXsdVisitor) simpleType(attr *FQN) string {
+	if !v.useType {
+		return "xs:string"
+	}
+	switch attr.useType {
+	case "int", "int64":
+		return "xs:integer"
+	case "bool":
+		return "xs:boolean"
+	case "float64":
+		return "xs:decimal"
+	default:
+		return "xs:string"
+	}
+}
+
+// nodeSimpleType is simpleType's counterpart for a leaf element, which
+// has no complexType of its own and so must carry an xs:simpleType
+// directly as its @type.
+func (v *PrintXsdVisitor) nodeSimpleType(node *Node) string {
+	if !v.useType {
+		return "xs:string"
+	}
+	switch node.useType {
+	case "int", "int64":
+		return "xs:integer"
+	case "bool":
+		return "xs:boolean"
+	case "float64":
+		return "xs:decimal"
+	default:
+		return "xs:string"
+	}
+}